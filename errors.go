@@ -1,159 +1,24 @@
 package registry
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/docker/docker-registry/digest"
+	"github.com/docker/docker-registry/registry/api/errcode"
+	"github.com/docker/docker-registry/registry/context"
 	"github.com/docker/docker-registry/storage"
 )
 
-// ErrorCode represents the error type. The errors are serialized via strings
-// and the integer format may change and should *never* be exported.
-type ErrorCode int
-
-const (
-	// ErrorCodeUnknown is a catch-all for errors not defined below.
-	ErrorCodeUnknown ErrorCode = iota
-
-	// The following errors can happen during a layer upload.
-
-	// ErrorCodeInvalidDigest is returned when uploading a layer if the
-	// provided digest does not match the layer contents.
-	ErrorCodeInvalidDigest
-
-	// ErrorCodeInvalidLength is returned when uploading a layer if the provided
-	// length does not match the content length.
-	ErrorCodeInvalidLength
-
-	// ErrorCodeInvalidName is returned when the name in the manifest does not
-	// match the provided name.
-	ErrorCodeInvalidName
-
-	// ErrorCodeInvalidTag is returned when the tag in the manifest does not
-	// match the provided tag.
-	ErrorCodeInvalidTag
-
-	// ErrorCodeUnknownRepository when the repository name is not known.
-	ErrorCodeUnknownRepository
-
-	// ErrorCodeUnknownManifest returned when image manifest name and tag is
-	// unknown, accompanied by a 404 status.
-	ErrorCodeUnknownManifest
-
-	// ErrorCodeInvalidManifest returned when an image manifest is invalid,
-	// typically during a PUT operation.
-	ErrorCodeInvalidManifest
-
-	// ErrorCodeUnverifiedManifest is returned when the manifest fails signature
-	// validation.
-	ErrorCodeUnverifiedManifest
-
-	// ErrorCodeUnknownLayer is returned when the manifest references a
-	// nonexistent layer.
-	ErrorCodeUnknownLayer
-
-	// ErrorCodeUnknownLayerUpload is returned when an upload is accessed.
-	ErrorCodeUnknownLayerUpload
-
-	// ErrorCodeUntrustedSignature is returned when the manifest is signed by an
-	// untrusted source.
-	ErrorCodeUntrustedSignature
-)
-
-var errorCodeStrings = map[ErrorCode]string{
-	ErrorCodeUnknown:            "UNKNOWN",
-	ErrorCodeInvalidDigest:      "INVALID_DIGEST",
-	ErrorCodeInvalidLength:      "INVALID_LENGTH",
-	ErrorCodeInvalidName:        "INVALID_NAME",
-	ErrorCodeInvalidTag:         "INVALID_TAG",
-	ErrorCodeUnknownRepository:  "UNKNOWN_REPOSITORY",
-	ErrorCodeUnknownManifest:    "UNKNOWN_MANIFEST",
-	ErrorCodeInvalidManifest:    "INVALID_MANIFEST",
-	ErrorCodeUnverifiedManifest: "UNVERIFIED_MANIFEST",
-	ErrorCodeUnknownLayer:       "UNKNOWN_LAYER",
-	ErrorCodeUnknownLayerUpload: "UNKNOWN_LAYER_UPLOAD",
-	ErrorCodeUntrustedSignature: "UNTRUSTED_SIGNATURE",
-}
-
-var errorCodesMessages = map[ErrorCode]string{
-	ErrorCodeUnknown:            "unknown error",
-	ErrorCodeInvalidDigest:      "provided digest did not match uploaded content",
-	ErrorCodeInvalidLength:      "provided length did not match content length",
-	ErrorCodeInvalidName:        "manifest name did not match URI",
-	ErrorCodeInvalidTag:         "manifest tag did not match URI",
-	ErrorCodeUnknownRepository:  "repository not known to registry",
-	ErrorCodeUnknownManifest:    "manifest not known",
-	ErrorCodeInvalidManifest:    "manifest is invalid",
-	ErrorCodeUnverifiedManifest: "manifest failed signature validation",
-	ErrorCodeUnknownLayer:       "referenced layer not available",
-	ErrorCodeUnknownLayerUpload: "cannot resume unknown layer upload",
-	ErrorCodeUntrustedSignature: "manifest signed by untrusted source",
-}
-
-var stringToErrorCode map[string]ErrorCode
-
-func init() {
-	stringToErrorCode = make(map[string]ErrorCode, len(errorCodeStrings))
-
-	// Build up reverse error code map
-	for k, v := range errorCodeStrings {
-		stringToErrorCode[v] = k
-	}
-}
-
-// ParseErrorCode attempts to parse the error code string, returning
-// ErrorCodeUnknown if the error is not known.
-func ParseErrorCode(s string) ErrorCode {
-	ec, ok := stringToErrorCode[s]
-
-	if !ok {
-		return ErrorCodeUnknown
-	}
-
-	return ec
-}
-
-// String returns the canonical identifier for this error code.
-func (ec ErrorCode) String() string {
-	s, ok := errorCodeStrings[ec]
-
-	if !ok {
-		return errorCodeStrings[ErrorCodeUnknown]
-	}
-
-	return s
-}
-
-// Message returned the human-readable error message for this error code.
-func (ec ErrorCode) Message() string {
-	m, ok := errorCodesMessages[ec]
-
-	if !ok {
-		return errorCodesMessages[ErrorCodeUnknown]
-	}
-
-	return m
-}
-
-// MarshalText encodes the receiver into UTF-8-encoded text and returns the
-// result.
-func (ec ErrorCode) MarshalText() (text []byte, err error) {
-	return []byte(ec.String()), nil
-}
-
-// UnmarshalText decodes the form generated by MarshalText.
-func (ec *ErrorCode) UnmarshalText(text []byte) error {
-	*ec = stringToErrorCode[string(text)]
-
-	return nil
-}
-
-// Error provides a wrapper around ErrorCode with extra Details provided.
+// Error provides a wrapper around an errcode.ErrorCode with extra Detail
+// provided. The set of valid codes, their string values and default HTTP
+// status codes are defined by the errcode package.
 type Error struct {
-	Code    ErrorCode   `json:"code"`
-	Message string      `json:"message,omitempty"`
-	Detail  interface{} `json:"detail,omitempty"`
+	Code    errcode.ErrorCode `json:"code"`
+	Message string            `json:"message,omitempty"`
+	Detail  interface{}       `json:"detail,omitempty"`
 }
 
 // Error returns a human readable representation of the error.
@@ -163,41 +28,68 @@ func (e Error) Error() string {
 		e.Message)
 }
 
+// ErrorCode returns e.Code, satisfying errcode.ErrorCoder so that
+// errcode.ServeJSON(w, err) derives the right status from a single Error
+// without needing to special-case it.
+func (e Error) ErrorCode() errcode.ErrorCode {
+	return e.Code
+}
+
 // Errors provides the envelope for multiple errors and a few sugar methods
 // for use within the application.
 type Errors struct {
 	Errors []error `json:"errors,omitempty"`
 }
 
-// Push pushes an error on to the error stack, with the optional detail
-// argument. It is a programming error (ie panic) to push more than one
-// detail at a time.
-func (errs *Errors) Push(code ErrorCode, details ...interface{}) {
-	if len(details) > 1 {
-		panic("please specify zero or one detail items for this error")
-	}
-
+// Push pushes an error on to the error stack, with optional arguments.
+// If the descriptor's message contains formatting verbs, args are used to
+// format it via fmt.Sprintf and no Detail is recorded; otherwise a single
+// trailing arg, if present, is recorded as the error's Detail (an error
+// value is recorded as its Error() string). It is a programming error (ie
+// panic) to pass more than one non-formatting argument.
+func (errs *Errors) Push(code errcode.ErrorCode, args ...interface{}) {
 	var detail interface{}
-	if len(details) > 0 {
-		detail = details[0]
-	}
-
-	if err, ok := detail.(error); ok {
-		detail = err.Error()
+	message := code.Message()
+
+	switch {
+	case len(args) == 0:
+	case strings.Contains(message, "%"):
+		message = fmt.Sprintf(message, args...)
+	case len(args) == 1:
+		detail = args[0]
+		if err, ok := detail.(error); ok {
+			detail = err.Error()
+		}
+	default:
+		panic("please specify zero or one detail items for this error")
 	}
 
 	errs.PushErr(Error{
 		Code:    code,
-		Message: code.Message(),
+		Message: message,
 		Detail:  detail,
 	})
 }
 
-// PushErr pushes an error interface onto the error stack.
+// PushErr pushes an error interface onto the error stack. Recognized
+// registry error types are mapped to their corresponding ErrorCode and a
+// structured Detail payload, so handler code can push a domain error
+// directly instead of duplicating that mapping at every call site.
 func (errs *Errors) PushErr(err error) {
-	switch err.(type) {
+	switch e := err.(type) {
 	case Error:
-		errs.Errors = append(errs.Errors, err)
+		errs.Errors = append(errs.Errors, e)
+	case *BlobNotFoundError:
+		errs.Push(errcode.ErrorCodeBlobUnknown, DetailManifestBlobUnknown{Name: e.Name, Digest: e.Digest})
+	case *ImageManifestNotFoundError:
+		errs.Push(errcode.ErrorCodeManifestUnknown, DetailManifestUnknown{Name: e.Name, Tag: e.Tag})
+	case *BlobUploadNotFoundError:
+		errs.Push(errcode.ErrorCodeBlobUploadUnknown)
+	case *BlobUploadInvalidRangeError:
+		errs.Push(errcode.ErrorCodeBlobUploadInvalid, DetailBlobUploadInvalidRange{
+			LastValidRange: e.LastValidRange,
+			BlobSize:       e.BlobSize,
+		})
 	default:
 		errs.Errors = append(errs.Errors, Error{Message: err.Error()})
 	}
@@ -218,6 +110,140 @@ func (errs *Errors) Error() string {
 	}
 }
 
+// Err returns nil if errs is empty and errs itself otherwise, so callers can
+// thread an Errors value through a function's error return without an
+// explicit length check at the call site.
+func (errs *Errors) Err() error {
+	if errs.Len() == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// ErrorCode returns the code of the dominant error among those pushed, using
+// the same precedence as ServeHTTP. It satisfies errcode.ErrorCoder so that
+// errcode.ServeJSON(w, errs) picks the right status without errs needing to
+// be handled as a special case.
+func (errs *Errors) ErrorCode() errcode.ErrorCode {
+	if e, ok := errs.dominantError(); ok {
+		return e.Code
+	}
+
+	return errcode.ErrorCodeUnknown
+}
+
+// ServeHTTP serializes errs as a JSON error envelope and writes it to w,
+// selecting the response status from the dominant error among those pushed.
+// The envelope's request_id field echoes the RequestIDHeader response
+// header set by registry/context's RequestID middleware, correlating the
+// response with that request's logs.
+func (errs *Errors) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sc := http.StatusInternalServerError
+	if e, ok := errs.dominantError(); ok {
+		sc = e.Code.Descriptor().HTTPStatusCode
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(sc)
+
+	json.NewEncoder(w).Encode(struct {
+		Errors    []Error `json:"errors,omitempty"`
+		RequestID string  `json:"request_id,omitempty"`
+	}{
+		Errors:    errs.errors(),
+		RequestID: w.Header().Get(context.RequestIDHeader),
+	})
+}
+
+// errors coerces each pushed error into a concrete Error, so that
+// MarshalJSON and ServeHTTP can serialize them without losing Code to a
+// generic error's Error() string.
+func (errs *Errors) errors() []Error {
+	out := make([]Error, 0, len(errs.Errors))
+	for _, err := range errs.Errors {
+		if e, ok := err.(Error); ok {
+			out = append(out, e)
+			continue
+		}
+
+		out = append(out, Error{Message: err.Error()})
+	}
+
+	return out
+}
+
+// dominantError returns the Error among those pushed that should determine
+// the envelope's overall HTTP status. Any client error (4xx) outranks any
+// non-client error, UNAUTHORIZED outranks NOT_FOUND outranks BAD_REQUEST,
+// and ties are broken by first-seen order. It returns false if errs is
+// empty or contains no concrete Error values.
+func (errs *Errors) dominantError() (Error, bool) {
+	var (
+		best      Error
+		bestScore = -1
+		found     bool
+	)
+
+	for _, err := range errs.Errors {
+		e, ok := err.(Error)
+		if !ok {
+			continue
+		}
+
+		if score := statusCodeScore(e.Code.Descriptor().HTTPStatusCode); score > bestScore {
+			best, bestScore, found = e, score, true
+		}
+	}
+
+	return best, found
+}
+
+// statusCodeScore ranks HTTP status codes for dominant-error selection.
+func statusCodeScore(sc int) int {
+	switch sc {
+	case http.StatusUnauthorized:
+		return 30
+	case http.StatusNotFound:
+		return 20
+	case http.StatusBadRequest:
+		return 10
+	default:
+		if sc >= 400 && sc < 500 {
+			return 5
+		}
+		return 0
+	}
+}
+
+// MarshalJSON converts errs into the standard error envelope, coercing any
+// non-Error values pushed onto the stack into a concrete Error so that
+// UnmarshalJSON can round-trip them without losing their Code.
+func (errs *Errors) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Errors []Error `json:"errors,omitempty"`
+	}{Errors: errs.errors()})
+}
+
+// UnmarshalJSON decodes the form generated by MarshalJSON, restoring each
+// element as a concrete Error so its Code survives the round-trip.
+func (errs *Errors) UnmarshalJSON(data []byte) error {
+	envelope := struct {
+		Errors []Error `json:"errors,omitempty"`
+	}{}
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	errs.Errors = make([]error, len(envelope.Errors))
+	for i, e := range envelope.Errors {
+		errs.Errors[i] = e
+	}
+
+	return nil
+}
+
 // Clear clears the errors.
 func (errs *Errors) Clear() {
 	errs.Errors = errs.Errors[:0]
@@ -238,6 +264,57 @@ type DetailUnknownLayer struct {
 	Unknown storage.FSLayer `json:"unknown"`
 }
 
+// DetailInvalidDigest provides detail for ErrorCodeDigestInvalid, identifying
+// the digest that was provided and, where known, the reason it did not
+// match the uploaded content.
+type DetailInvalidDigest struct {
+	Digest digest.Digest `json:"digest"`
+	Reason error         `json:"reason,omitempty"`
+}
+
+// DetailInvalidLength provides detail for ErrorCodeSizeInvalid, identifying
+// the length that was provided against the length actually observed.
+type DetailInvalidLength struct {
+	Provided int `json:"provided"`
+	Actual   int `json:"actual"`
+}
+
+// DetailInvalidName provides detail for ErrorCodeNameInvalid, identifying the
+// repository name that failed validation.
+type DetailInvalidName struct {
+	Name string `json:"name"`
+}
+
+// DetailInvalidTag provides detail for ErrorCodeTagInvalid, identifying the
+// tag that failed validation.
+type DetailInvalidTag struct {
+	Tag string `json:"tag"`
+}
+
+// DetailManifestUnknown provides detail for ErrorCodeManifestUnknown,
+// identifying the repository name and tag that were requested.
+type DetailManifestUnknown struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+}
+
+// DetailBlobUploadInvalidRange provides detail for
+// ErrorCodeBlobUploadInvalid when the error is the result of an out of
+// order chunk, giving the client the information it needs to resume the
+// upload at the correct offset.
+type DetailBlobUploadInvalidRange struct {
+	LastValidRange int `json:"lastValidRange"`
+	BlobSize       int `json:"blobSize"`
+}
+
+// DetailManifestBlobUnknown provides detail for ErrorCodeManifestBlobUnknown
+// and ErrorCodeBlobUnknown, identifying the repository name and blob digest
+// that the registry does not have.
+type DetailManifestBlobUnknown struct {
+	Name   string        `json:"name"`
+	Digest digest.Digest `json:"digest"`
+}
+
 // RepositoryNotFoundError is returned when making an operation against a
 // repository that does not exist in the registry.
 type RepositoryNotFoundError struct {
@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker-registry/registry/api/errcode"
+	"github.com/docker/docker-registry/registry/context"
+)
+
+func TestErrorsErr(t *testing.T) {
+	var errs Errors
+	if err := errs.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil for an empty Errors", err)
+	}
+
+	errs.Push(errcode.ErrorCodeUnknown)
+	if err := errs.Err(); err != &errs {
+		t.Errorf("Err() = %v, want errs itself once non-empty", err)
+	}
+}
+
+func TestDominantErrorPrecedence(t *testing.T) {
+	// UNAUTHORIZED should win regardless of push order, even over the
+	// first-seen NOT_FOUND and BAD_REQUEST entries.
+	var errs Errors
+	errs.Push(errcode.ErrorCodeBlobUploadInvalid) // BAD_REQUEST
+	errs.Push(errcode.ErrorCodeBlobUnknown)       // NOT_FOUND
+	errs.Push(errcode.ErrorCodeUnauthorized)      // UNAUTHORIZED
+	errs.Push(errcode.ErrorCodeManifestUnknown)   // NOT_FOUND again
+
+	dominant, ok := errs.dominantError()
+	if !ok {
+		t.Fatal("dominantError() returned false for a non-empty Errors")
+	}
+	if dominant.Code != errcode.ErrorCodeUnauthorized {
+		t.Errorf("dominant code = %v, want %v", dominant.Code, errcode.ErrorCodeUnauthorized)
+	}
+}
+
+func TestDominantErrorTiesBreakFirstSeen(t *testing.T) {
+	var errs Errors
+	errs.Push(errcode.ErrorCodeBlobUnknown)     // NOT_FOUND, pushed first
+	errs.Push(errcode.ErrorCodeManifestUnknown) // NOT_FOUND, pushed second
+
+	dominant, ok := errs.dominantError()
+	if !ok {
+		t.Fatal("dominantError() returned false for a non-empty Errors")
+	}
+	if dominant.Code != errcode.ErrorCodeBlobUnknown {
+		t.Errorf("dominant code = %v, want first-seen %v", dominant.Code, errcode.ErrorCodeBlobUnknown)
+	}
+}
+
+func TestErrorsServeHTTP(t *testing.T) {
+	var errs Errors
+	errs.Push(errcode.ErrorCodeBlobUnknown)
+	errs.Push(errcode.ErrorCodeUnauthorized)
+
+	w := httptest.NewRecorder()
+	w.Header().Set(context.RequestIDHeader, "test-request-id")
+	r := httptest.NewRequest("GET", "/v2/foo/bar/blobs/sha256:abc", nil)
+
+	errs.ServeHTTP(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	var body struct {
+		Errors    []Error `json:"errors"`
+		RequestID string  `json:"request_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal response body: %v", err)
+	}
+
+	if len(body.Errors) != 2 {
+		t.Fatalf("len(body.Errors) = %d, want 2", len(body.Errors))
+	}
+	if body.RequestID != "test-request-id" {
+		t.Errorf("RequestID = %q, want %q", body.RequestID, "test-request-id")
+	}
+}
+
+func TestErrorsJSONRoundTrip(t *testing.T) {
+	var errs Errors
+	errs.Push(errcode.ErrorCodeBlobUnknown, DetailManifestBlobUnknown{Name: "foo/bar", Digest: "sha256:abc"})
+	errs.Push(errcode.ErrorCodeUnauthorized)
+
+	data, err := json.Marshal(&errs)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var roundTripped Errors
+	if err := json.Unmarshal(data, &roundTripped); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if roundTripped.Len() != errs.Len() {
+		t.Fatalf("Len() = %d, want %d", roundTripped.Len(), errs.Len())
+	}
+
+	for i, want := range errs.errors() {
+		got, ok := roundTripped.Errors[i].(Error)
+		if !ok {
+			t.Fatalf("element %d = %#v, want a concrete Error", i, roundTripped.Errors[i])
+		}
+		if got.Code != want.Code {
+			t.Errorf("element %d Code = %v, want %v", i, got.Code, want.Code)
+		}
+		if got.Message != want.Message {
+			t.Errorf("element %d Message = %q, want %q", i, got.Message, want.Message)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker-registry/digest"
+	"github.com/docker/docker-registry/registry/api/errcode"
+)
+
+// errCodeTestFormatted is a fixture code whose message contains a
+// formatting verb, exercising the branch of Push that none of the
+// registered v2 codes happen to need.
+var errCodeTestFormatted = errcode.Register("errcode.test", errcode.ErrorDescriptor{
+	Value:          "TEST_FORMATTED",
+	Message:        "sorry %q isn't valid",
+	HTTPStatusCode: http.StatusBadRequest,
+})
+
+func TestPushFormatsMessageWhenDescriptorHasVerbs(t *testing.T) {
+	var errs Errors
+	errs.Push(errCodeTestFormatted, "widget")
+
+	if errs.Len() != 1 {
+		t.Fatalf("expected 1 error, got %d", errs.Len())
+	}
+
+	got := errs.Errors[0].(Error)
+	if want := `sorry "widget" isn't valid`; got.Message != want {
+		t.Errorf("Message = %q, want %q", got.Message, want)
+	}
+	if got.Detail != nil {
+		t.Errorf("Detail = %#v, want nil when formatting consumes the args", got.Detail)
+	}
+}
+
+func TestPushRecordsDetailWhenDescriptorHasNoVerbs(t *testing.T) {
+	var errs Errors
+	errs.Push(errcode.ErrorCodeBlobUnknown, DetailManifestBlobUnknown{Name: "foo/bar", Digest: digest.Digest("sha256:abc")})
+
+	got := errs.Errors[0].(Error)
+	if got.Message != errcode.ErrorCodeBlobUnknown.Message() {
+		t.Errorf("Message = %q, want descriptor message %q", got.Message, errcode.ErrorCodeBlobUnknown.Message())
+	}
+
+	detail, ok := got.Detail.(DetailManifestBlobUnknown)
+	if !ok {
+		t.Fatalf("Detail = %#v, want DetailManifestBlobUnknown", got.Detail)
+	}
+	if detail.Name != "foo/bar" {
+		t.Errorf("Detail.Name = %q, want %q", detail.Name, "foo/bar")
+	}
+}
+
+func TestPushPanicsWithMultipleNonFormatArgs(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Push to panic with more than one non-formatting arg")
+		}
+	}()
+
+	var errs Errors
+	errs.Push(errcode.ErrorCodeBlobUnknown, "one", "two")
+}
+
+func TestPushErrPreservesNameFromDomainErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code errcode.ErrorCode
+	}{
+		{
+			name: "blob not found",
+			err:  &BlobNotFoundError{Name: "foo/bar", Digest: digest.Digest("sha256:abc")},
+			code: errcode.ErrorCodeBlobUnknown,
+		},
+		{
+			name: "manifest not found",
+			err:  &ImageManifestNotFoundError{Name: "foo/bar", Tag: "latest"},
+			code: errcode.ErrorCodeManifestUnknown,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var errs Errors
+			errs.PushErr(c.err)
+
+			got := errs.Errors[0].(Error)
+			if got.Code != c.code {
+				t.Errorf("Code = %v, want %v", got.Code, c.code)
+			}
+
+			switch detail := got.Detail.(type) {
+			case DetailManifestBlobUnknown:
+				if detail.Name != "foo/bar" {
+					t.Errorf("Detail.Name = %q, want %q", detail.Name, "foo/bar")
+				}
+			case DetailManifestUnknown:
+				if detail.Name != "foo/bar" || detail.Tag != "latest" {
+					t.Errorf("Detail = %#v, want Name=foo/bar Tag=latest", detail)
+				}
+			default:
+				t.Fatalf("unexpected detail type %#v", got.Detail)
+			}
+		})
+	}
+}
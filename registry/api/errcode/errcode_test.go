@@ -0,0 +1,112 @@
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterPanicsOnDuplicateValue(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Register to panic on a duplicate Value")
+		}
+	}()
+
+	Register("errcode.test", ErrorDescriptor{Value: "DUPLICATE_TEST_VALUE", HTTPStatusCode: http.StatusTeapot})
+	Register("errcode.test", ErrorDescriptor{Value: "DUPLICATE_TEST_VALUE", HTTPStatusCode: http.StatusTeapot})
+}
+
+func TestParseErrorCodeFallsBackToUnknown(t *testing.T) {
+	if got := ParseErrorCode("NOT_A_REGISTERED_VALUE"); got != ErrorCodeUnknown {
+		t.Errorf("ParseErrorCode(unregistered) = %v, want ErrorCodeUnknown", got)
+	}
+}
+
+func TestDescriptorFallsBackToUnknown(t *testing.T) {
+	unregistered := ErrorCode(-1)
+
+	if got := unregistered.Descriptor(); got.Code != ErrorCodeUnknown {
+		t.Errorf("Descriptor() for an unregistered code = %#v, want the Unknown descriptor", got)
+	}
+	if got := unregistered.String(); got != ErrorCodeUnknown.String() {
+		t.Errorf("String() for an unregistered code = %q, want %q", got, ErrorCodeUnknown.String())
+	}
+}
+
+func TestErrorCodeTextRoundTrip(t *testing.T) {
+	text, err := ErrorCodeBlobUnknown.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText: %v", err)
+	}
+
+	var ec ErrorCode
+	if err := ec.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText: %v", err)
+	}
+	if ec != ErrorCodeBlobUnknown {
+		t.Errorf("round-tripped code = %v, want %v", ec, ErrorCodeBlobUnknown)
+	}
+}
+
+// testCoder is a minimal ErrorCoder fixture, independent of registry.Error,
+// for pinning down ServeJSON's status-derivation behavior.
+type testCoder struct {
+	Code ErrorCode `json:"code"`
+}
+
+func (c testCoder) Error() string        { return c.Code.Message() }
+func (c testCoder) ErrorCode() ErrorCode { return c.Code }
+
+func TestServeJSONDerivesStatusFromErrorCoder(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	if err := ServeJSON(w, testCoder{Code: ErrorCodeUnauthorized}); err != nil {
+		t.Fatalf("ServeJSON: %v", err)
+	}
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	var body struct {
+		Errors []struct {
+			Code ErrorCode `json:"code"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal response body: %v", err)
+	}
+	if len(body.Errors) != 1 {
+		t.Fatalf("len(body.Errors) = %d, want 1", len(body.Errors))
+	}
+	if body.Errors[0].Code != ErrorCodeUnauthorized {
+		t.Errorf("serialized code = %v, want %v", body.Errors[0].Code, ErrorCodeUnauthorized)
+	}
+}
+
+func TestServeJSONFallsBackToMessageForPlainErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	if err := ServeJSON(w, errors.New("boom")); err != nil {
+		t.Fatalf("ServeJSON: %v", err)
+	}
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	var body struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal response body: %v", err)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Message != "boom" {
+		t.Fatalf("body.Errors = %#v, want a single element with Message %q", body.Errors, "boom")
+	}
+}
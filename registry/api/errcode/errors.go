@@ -0,0 +1,127 @@
+// Package errcode provides the error codes used across the registry API,
+// along with a registry that associates each code with a descriptor. The
+// descriptors double as the source of truth for the API documentation's
+// error table, enumerable per group via GroupNames and GroupDescriptors,
+// and let handlers derive an HTTP status code instead of hand-coding one.
+package errcode
+
+import (
+	"fmt"
+)
+
+// ErrorCode represents the error type. The errors are serialized via
+// ErrorCode.String(), so the underlying integer value may change between
+// runs and must never be depended on or exposed outside of this package.
+type ErrorCode int
+
+// ErrorDescriptor provides relevant information about a given error code.
+type ErrorDescriptor struct {
+	// Code is the error code that this descriptor describes.
+	Code ErrorCode
+
+	// Value provides a unique, string key, usually all caps with
+	// underscores, that identifies the error code in serialized form. This
+	// is the value emitted in API responses.
+	Value string
+
+	// Message is a short, human readable phrase for the error condition,
+	// suitable for inclusion in API responses.
+	Message string
+
+	// Description provides a complete account of the error's purpose,
+	// suitable for use in generated documentation.
+	Description string
+
+	// HTTPStatusCode is the http status code that is associated with this
+	// error condition.
+	HTTPStatusCode int
+}
+
+var (
+	errorCodeToDescriptors = map[ErrorCode]ErrorDescriptor{}
+	stringToDescriptor     = map[string]ErrorDescriptor{}
+	groupToDescriptors     = map[string][]ErrorDescriptor{}
+
+	nextCode = 1
+)
+
+// Register will make the passed-in error code known to the environment and
+// return a new ErrorCode for it. It panics if the descriptor's Value has
+// already been registered, since that represents a programming error on the
+// part of the caller, not a runtime condition.
+func Register(group string, descriptor ErrorDescriptor) ErrorCode {
+	descriptor.Code = ErrorCode(nextCode)
+
+	if _, ok := stringToDescriptor[descriptor.Value]; ok {
+		panic(fmt.Sprintf("errcode: value %q is already registered", descriptor.Value))
+	}
+
+	groupToDescriptors[group] = append(groupToDescriptors[group], descriptor)
+	errorCodeToDescriptors[descriptor.Code] = descriptor
+	stringToDescriptor[descriptor.Value] = descriptor
+
+	nextCode++
+
+	return descriptor.Code
+}
+
+// ParseErrorCode attempts to parse the error code string, returning
+// ErrorCodeUnknown if the error is not known.
+func ParseErrorCode(value string) ErrorCode {
+	d, ok := stringToDescriptor[value]
+	if !ok {
+		return ErrorCodeUnknown
+	}
+
+	return d.Code
+}
+
+// GroupNames returns the name of each group that has registered error codes,
+// primarily for use by the spec error-table generator.
+func GroupNames() []string {
+	names := make([]string, 0, len(groupToDescriptors))
+	for name := range groupToDescriptors {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// GroupDescriptors returns the descriptors registered under group, in
+// registration order.
+func GroupDescriptors(group string) []ErrorDescriptor {
+	return groupToDescriptors[group]
+}
+
+// Descriptor returns the descriptor for the error code.
+func (ec ErrorCode) Descriptor() ErrorDescriptor {
+	d, ok := errorCodeToDescriptors[ec]
+	if !ok {
+		return errorCodeToDescriptors[ErrorCodeUnknown]
+	}
+
+	return d
+}
+
+// String returns the canonical identifier for this error code.
+func (ec ErrorCode) String() string {
+	return ec.Descriptor().Value
+}
+
+// Message returns the human-readable error message for this error code.
+func (ec ErrorCode) Message() string {
+	return ec.Descriptor().Message
+}
+
+// MarshalText encodes the receiver into UTF-8-encoded text and returns the
+// result.
+func (ec ErrorCode) MarshalText() (text []byte, err error) {
+	return []byte(ec.String()), nil
+}
+
+// UnmarshalText decodes the form generated by MarshalText.
+func (ec *ErrorCode) UnmarshalText(text []byte) error {
+	*ec = ParseErrorCode(string(text))
+
+	return nil
+}
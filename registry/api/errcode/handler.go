@@ -0,0 +1,66 @@
+package errcode
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorCoder is implemented by error types that carry a single associated
+// ErrorCode, allowing ServeJSON to pick an appropriate HTTP status without
+// the caller needing to know the concrete error type.
+type ErrorCoder interface {
+	error
+	ErrorCode() ErrorCode
+}
+
+// ServeJSON serializes err as a JSON error envelope and writes it to w,
+// deriving the response status code from err's descriptor when err
+// implements ErrorCoder. If err does not carry a code, or the code has no
+// registered descriptor, a 500 Internal Server Error is assumed.
+func ServeJSON(w http.ResponseWriter, err error) error {
+	sc := http.StatusInternalServerError
+	if coder, ok := err.(ErrorCoder); ok {
+		sc = coder.ErrorCode().Descriptor().HTTPStatusCode
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(sc)
+
+	return json.NewEncoder(w).Encode(envelope(err))
+}
+
+// envelope builds the {"errors": [...]} body for err. A caller that already
+// has a {"errors": [...]} shaped value, such as an errcode-aware Errors
+// type with its own MarshalJSON, is passed through unchanged. Otherwise err
+// is marshaled on its own terms first, so a struct error carrying a Code
+// and Detail (eg a registry.Error) keeps them; only when that yields
+// nothing useful, as for errors.New's unexported *errorString, do we fall
+// back to a bare message.
+func envelope(err error) interface{} {
+	if _, ok := err.(json.Marshaler); ok {
+		return err
+	}
+
+	if encoded, marshalErr := json.Marshal(err); marshalErr == nil && !isEmptyJSON(encoded) {
+		return struct {
+			Errors []json.RawMessage `json:"errors"`
+		}{Errors: []json.RawMessage{encoded}}
+	}
+
+	return struct {
+		Errors []interface{} `json:"errors"`
+	}{Errors: []interface{}{struct {
+		Message string `json:"message,omitempty"`
+	}{Message: err.Error()}}}
+}
+
+// isEmptyJSON reports whether encoded carries no information, as produced
+// by marshaling a struct or pointer with no exported fields.
+func isEmptyJSON(encoded []byte) bool {
+	switch string(encoded) {
+	case "{}", "null", "[]", `""`:
+		return true
+	default:
+		return false
+	}
+}
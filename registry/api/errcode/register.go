@@ -0,0 +1,179 @@
+package errcode
+
+import "net/http"
+
+// errGroup is the group under which the general purpose registry API v2
+// error codes below are registered. Package-specific codes (eg those
+// belonging to the token auth package) should register under their own
+// group name.
+const errGroup = "registry.api.v2"
+
+var (
+	// ErrorCodeUnknown is a catch-all for errors not defined below.
+	ErrorCodeUnknown = Register(errGroup, ErrorDescriptor{
+		Value:          "UNKNOWN",
+		Message:        "unknown error",
+		Description:    `Generic error returned when the error does not have an API classification.`,
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeUnsupported is returned when an operation is not supported.
+	ErrorCodeUnsupported = Register(errGroup, ErrorDescriptor{
+		Value:          "UNSUPPORTED",
+		Message:        "The operation is unsupported.",
+		Description:    `The operation was unsupported due to a missing implementation or invalid set of parameters.`,
+		HTTPStatusCode: http.StatusMethodNotAllowed,
+	})
+
+	// ErrorCodeUnauthorized is returned if a request requires
+	// authentication.
+	ErrorCodeUnauthorized = Register(errGroup, ErrorDescriptor{
+		Value:          "UNAUTHORIZED",
+		Message:        "authentication required",
+		Description:    `The access controller was unable to authenticate the client. Often this will be accompanied by a Www-Authenticate HTTP response header indicating how to authenticate.`,
+		HTTPStatusCode: http.StatusUnauthorized,
+	})
+
+	// ErrorCodeDenied is returned if a client does not have sufficient
+	// permission to perform an action.
+	ErrorCodeDenied = Register(errGroup, ErrorDescriptor{
+		Value:          "DENIED",
+		Message:        "requested access to the resource is denied",
+		Description:    `The access controller denied access for the operation on a resource.`,
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
+	// ErrorCodeDigestInvalid is returned when uploading a blob if the
+	// provided digest does not match the blob contents.
+	ErrorCodeDigestInvalid = Register(errGroup, ErrorDescriptor{
+		Value:          "DIGEST_INVALID",
+		Message:        "provided digest did not match uploaded content",
+		Description:    `When a blob is uploaded, the registry will check that the content matches the digest provided by the client. The error may include a detail structure with the key "digest" that specifies the invalid digest string. This error may also be returned when a manifest includes an invalid layer digest.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeSizeInvalid is returned when uploading a blob if the
+	// provided length does not match the content length.
+	ErrorCodeSizeInvalid = Register(errGroup, ErrorDescriptor{
+		Value:          "SIZE_INVALID",
+		Message:        "provided length did not match content length",
+		Description:    `When a layer is uploaded, the provided size will be checked against the uploaded content. If they do not match, this error will be returned.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeNameInvalid is returned when the name in the manifest does
+	// not match the provided name.
+	ErrorCodeNameInvalid = Register(errGroup, ErrorDescriptor{
+		Value:          "NAME_INVALID",
+		Message:        "invalid repository name",
+		Description:    `Invalid repository name encountered either during manifest validation or any API operation.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeTagInvalid is returned when the tag in the manifest does not
+	// match the provided tag.
+	ErrorCodeTagInvalid = Register(errGroup, ErrorDescriptor{
+		Value:          "TAG_INVALID",
+		Message:        "manifest tag did not match URI",
+		Description:    `During a manifest upload, if the tag in the manifest does not match the uri tag, this error will be returned.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeNameUnknown is returned when the repository name is not
+	// known.
+	ErrorCodeNameUnknown = Register(errGroup, ErrorDescriptor{
+		Value:          "NAME_UNKNOWN",
+		Message:        "repository name not known to registry",
+		Description:    `This is returned if the name used during an operation is unknown to the registry.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeManifestUnknown is returned when the manifest, identified by
+	// name and tag, is unknown to the repository.
+	ErrorCodeManifestUnknown = Register(errGroup, ErrorDescriptor{
+		Value:          "MANIFEST_UNKNOWN",
+		Message:        "manifest unknown",
+		Description:    `This error is returned when the manifest, identified by name and tag is unknown to the repository.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeManifestInvalid is returned when an image manifest is
+	// invalid, typically during a PUT operation.
+	ErrorCodeManifestInvalid = Register(errGroup, ErrorDescriptor{
+		Value:          "MANIFEST_INVALID",
+		Message:        "manifest invalid",
+		Description:    `During upload, manifests undergo several checks ensuring validity. If those checks fail, this error may be returned, unless a more specific error is included. The detail will contain information the failed validation.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeManifestUnverified is returned when the manifest fails
+	// signature verification.
+	ErrorCodeManifestUnverified = Register(errGroup, ErrorDescriptor{
+		Value:          "MANIFEST_UNVERIFIED",
+		Message:        "manifest failed signature verification",
+		Description:    `During manifest upload, if the manifest fails signature verification, this error will be returned.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeManifestBlobUnknown is returned when a manifest blob is
+	// unknown to the registry.
+	ErrorCodeManifestBlobUnknown = Register(errGroup, ErrorDescriptor{
+		Value:          "MANIFEST_BLOB_UNKNOWN",
+		Message:        "blob unknown to registry",
+		Description:    `This error may be returned when a manifest blob is unknown to the registry.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeBlobUnknown is returned when a blob is unknown to the
+	// registry.
+	ErrorCodeBlobUnknown = Register(errGroup, ErrorDescriptor{
+		Value:          "BLOB_UNKNOWN",
+		Message:        "blob unknown to registry",
+		Description:    `This error may be returned when a blob is unknown to the registry in a specified repository. This can be returned with a standard get or if a manifest references an unknown layer during upload.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeBlobUploadUnknown is returned when an upload is unknown.
+	ErrorCodeBlobUploadUnknown = Register(errGroup, ErrorDescriptor{
+		Value:          "BLOB_UPLOAD_UNKNOWN",
+		Message:        "blob upload unknown to registry",
+		Description:    `If a blob upload has been cancelled or was never started, this error code may be returned.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeBlobUploadInvalid is returned when an upload is invalid.
+	ErrorCodeBlobUploadInvalid = Register(errGroup, ErrorDescriptor{
+		Value:          "BLOB_UPLOAD_INVALID",
+		Message:        "blob upload invalid",
+		Description:    `The blob upload encountered an error and can no longer proceed.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+
+	// ErrorCodeUnknownLayer is returned when the manifest references a
+	// layer that has not yet been transferred to the registry. Its detail
+	// is carried by registry.DetailUnknownLayer.
+	ErrorCodeUnknownLayer = Register(errGroup, ErrorDescriptor{
+		Value:          "UNKNOWN_LAYER",
+		Message:        "referenced layer not available",
+		Description:    `This error is returned when an image manifest references a layer that has not yet been transferred to the registry, typically during manifest upload.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeUnknownLayerUpload is returned when an upload is accessed
+	// using a location that is unknown to the registry.
+	ErrorCodeUnknownLayerUpload = Register(errGroup, ErrorDescriptor{
+		Value:          "UNKNOWN_LAYER_UPLOAD",
+		Message:        "cannot resume unknown layer upload",
+		Description:    `This error is returned when a client attempts to resume a layer upload at a location that is unknown to the registry, such as a cancelled, completed, or stale upload.`,
+		HTTPStatusCode: http.StatusNotFound,
+	})
+
+	// ErrorCodeUntrustedSignature is returned when the manifest is signed
+	// by a source that is not trusted.
+	ErrorCodeUntrustedSignature = Register(errGroup, ErrorDescriptor{
+		Value:          "UNTRUSTED_SIGNATURE",
+		Message:        "manifest signed by untrusted source",
+		Description:    `During manifest upload, if the manifest is signed by a source that the registry does not trust, this error will be returned.`,
+		HTTPStatusCode: http.StatusBadRequest,
+	})
+)
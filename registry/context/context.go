@@ -0,0 +1,48 @@
+// Package context provides request-scoped context.Context plumbing used to
+// correlate a request's logging and error responses. A server-assigned
+// request id, generated by RequestID before the handler chain runs, is
+// threaded through ctx and surfaced both in log fields, via GetLogger, and
+// in error envelopes, via registry.Errors.ServeHTTP.
+package context
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+const requestIDKey = "http.request.id"
+
+// WithRequestID returns a context that associates id as the request id for
+// the request being processed.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// GetRequestID returns the request id associated with ctx, or "" if none has
+// been set via WithRequestID.
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// GetLogger returns a logrus.Entry decorated with the request id, if one has
+// been set on ctx, plus one field per key in keys whose value is present on
+// ctx. A field's name is the string representation of its key, so callers
+// typically pass back the same key they gave to context.WithValue.
+func GetLogger(ctx context.Context, keys ...interface{}) *logrus.Entry {
+	fields := logrus.Fields{}
+
+	if id := GetRequestID(ctx); id != "" {
+		fields[requestIDKey] = id
+	}
+
+	for _, key := range keys {
+		if v := ctx.Value(key); v != nil {
+			fields[fmt.Sprint(key)] = v
+		}
+	}
+
+	return logrus.WithFields(fields)
+}
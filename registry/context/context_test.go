@@ -0,0 +1,38 @@
+package context
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestRequestIDRoundTrip(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "test-id")
+
+	if got := GetRequestID(ctx); got != "test-id" {
+		t.Errorf("GetRequestID() = %q, want %q", got, "test-id")
+	}
+}
+
+func TestGetRequestIDEmptyWhenUnset(t *testing.T) {
+	if got := GetRequestID(context.Background()); got != "" {
+		t.Errorf("GetRequestID() = %q, want empty string", got)
+	}
+}
+
+func TestGetLoggerIncludesRequestIDAndRequestedKeys(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "test-id")
+	ctx = context.WithValue(ctx, "some.key", "some-value")
+
+	entry := GetLogger(ctx, "some.key", "unset.key")
+
+	if got := entry.Data[requestIDKey]; got != "test-id" {
+		t.Errorf("logger field %q = %v, want %q", requestIDKey, got, "test-id")
+	}
+	if got := entry.Data["some.key"]; got != "some-value" {
+		t.Errorf("logger field %q = %v, want %q", "some.key", got, "some-value")
+	}
+	if _, ok := entry.Data["unset.key"]; ok {
+		t.Errorf("logger field %q should be absent when ctx has no value for it", "unset.key")
+	}
+}
@@ -0,0 +1,71 @@
+package context
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// RequestIDHeader is the response header carrying the request id generated
+// by RequestID, so that error envelopes and clients can correlate a
+// response with the request id already present in that request's logs.
+const RequestIDHeader = "X-Docker-Request-Id"
+
+const (
+	httpRequestMethod     = "http.request.method"
+	httpRequestURI        = "http.request.uri"
+	httpRequestRemoteAddr = "http.request.remoteaddr"
+	httpRequestDuration   = "http.request.duration"
+)
+
+// Handler is like http.Handler, but takes a context carrying request-scoped
+// values, such as the request id, as its first argument.
+type Handler func(ctx context.Context, w http.ResponseWriter, r *http.Request)
+
+// WithRequest returns a context that carries identifying details of r for
+// later retrieval via GetLogger.
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	ctx = context.WithValue(ctx, httpRequestMethod, r.Method)
+	ctx = context.WithValue(ctx, httpRequestURI, r.RequestURI)
+	ctx = context.WithValue(ctx, httpRequestRemoteAddr, r.RemoteAddr)
+
+	return ctx
+}
+
+// RequestID wraps next so that it runs with a context carrying a freshly
+// generated request id, which is also sent to the client as the
+// RequestIDHeader response header. Once next returns, a log line reporting
+// the request's method, URI, remote address and total duration is emitted.
+func RequestID(next Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := newRequestID()
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := WithRequestID(WithRequest(context.Background(), r), id)
+		start := time.Now()
+
+		next(ctx, w, r)
+
+		GetLogger(ctx, httpRequestMethod, httpRequestURI, httpRequestRemoteAddr).
+			WithField(httpRequestDuration, time.Since(start)).
+			Info("response completed")
+	}
+}
+
+// newRequestID returns a random, UUID-shaped identifier suitable for
+// correlating a single request's logs and error responses. It is not a
+// full RFC 4122 implementation, just enough entropy for that purpose.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,52 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestRequestIDSetsHeaderBeforeNextRuns(t *testing.T) {
+	var headerDuringNext string
+
+	next := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		headerDuringNext = w.Header().Get(RequestIDHeader)
+		if GetRequestID(ctx) == "" {
+			t.Error("expected ctx to carry a request id by the time next runs")
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/v2/foo/bar/tags/list", nil)
+
+	RequestID(next).ServeHTTP(w, r)
+
+	if headerDuringNext == "" {
+		t.Fatal("expected RequestIDHeader to be set before next runs")
+	}
+	if got := w.Header().Get(RequestIDHeader); got != headerDuringNext {
+		t.Errorf("response header = %q, want the same id observed in next (%q)", got, headerDuringNext)
+	}
+}
+
+func TestWithRequestPopulatesRequestFields(t *testing.T) {
+	r := httptest.NewRequest("GET", "/v2/foo/bar/tags/list", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	ctx := WithRequest(context.Background(), r)
+
+	entry := GetLogger(ctx, httpRequestMethod, httpRequestURI, httpRequestRemoteAddr)
+
+	if got := entry.Data[httpRequestMethod]; got != "GET" {
+		t.Errorf("%s = %v, want %q", httpRequestMethod, got, "GET")
+	}
+	if got := entry.Data[httpRequestURI]; got != r.RequestURI {
+		t.Errorf("%s = %v, want %q", httpRequestURI, got, r.RequestURI)
+	}
+	if got := entry.Data[httpRequestRemoteAddr]; got != "10.0.0.1:1234" {
+		t.Errorf("%s = %v, want %q", httpRequestRemoteAddr, got, "10.0.0.1:1234")
+	}
+}